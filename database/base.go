@@ -1,37 +1,44 @@
 package database
 
 import (
-	"context"
 	"fmt"
+	"os"
 	"path"
-	"strings"
+	"path/filepath"
+	"time"
 
-	"github.com/google/shlex"
-	"github.com/rgzr/sshtun"
 	"github.com/spf13/viper"
 
 	"github.com/gobackup/gobackup/config"
 	"github.com/gobackup/gobackup/helper"
+	"github.com/gobackup/gobackup/hook"
 	"github.com/gobackup/gobackup/logger"
+	"github.com/gobackup/gobackup/notifier"
+	"github.com/gobackup/gobackup/sshtunnel"
 )
 
 // Base database
 type Base struct {
-	model            config.ModelConfig
-	dbConfig         config.SubConfig
-	viper            *viper.Viper
-	name             string
-	dumpPath         string
-	sshHost          string
-	sshPort          int
-	sshUser          string
-	sshPassword      string
-	sshKeyFile       string
-	tunnelLocalPort  int
-	tunnelRemotePort int
-	tunnelDbHost     string
-	cTunnelEnd       chan struct{}
-	cTunnelStart     chan struct{}
+	model                 config.ModelConfig
+	dbConfig              config.SubConfig
+	viper                 *viper.Viper
+	name                  string
+	dumpPath              string
+	dumpSize              int64
+	sshHost               string
+	sshPort               int
+	sshUser               string
+	sshPassword           string
+	sshKeyFile            string
+	sshProxyJump          []string
+	knownHostsFile        string
+	strictHostKeyChecking bool
+	sshKeepalive          time.Duration
+	tunnelLocalPort       int
+	tunnelRemotePort      int
+	tunnelDbHost          string
+	tunnelClient          *sshtunnel.Client
+	stopForward           func()
 }
 
 // Database interface
@@ -52,8 +59,12 @@ func newBase(model config.ModelConfig, dbConfig config.SubConfig) (base Base) {
 	base.sshHost = viper.GetString("ssh_host")
 	base.sshPort = viper.GetInt("ssh_port")
 	base.sshUser = viper.GetString("ssh_user")
-	base.sshPassword = viper.GetString("ssh_password")
+	base.sshPassword = helper.GetStringWithFile(viper.GetViper(), "ssh_password")
 	base.sshKeyFile = viper.GetString("ssh_key_file")
+	base.sshProxyJump = viper.GetStringSlice("ssh_proxy_jump")
+	base.knownHostsFile = viper.GetString("ssh_known_hosts_file")
+	base.strictHostKeyChecking = viper.GetBool("ssh_strict_host_key_checking")
+	base.sshKeepalive = viper.GetDuration("ssh_keepalive_interval")
 	base.tunnelDbHost = viper.GetString("tunnel_db_host")
 	base.tunnelRemotePort = viper.GetInt("tunnel_remote_port")
 	base.tunnelLocalPort = viper.GetInt("tunnel_local_port")
@@ -65,36 +76,6 @@ func newBase(model config.ModelConfig, dbConfig config.SubConfig) (base Base) {
 	return
 }
 
-func runHook(action, script string) error {
-	logger := logger.Tag("Database")
-	if len(script) == 0 {
-		return nil
-	}
-	logger.Infof("Run %s", action)
-	ignoreError := strings.HasPrefix(script, "-")
-	script = strings.TrimPrefix(script, "-")
-	c, err := shlex.Split(script)
-	if err != nil {
-		if ignoreError {
-			logger.Infof("Skip %s with error: %v", action, err)
-		} else {
-			return err
-		}
-	} else {
-		if _, err := helper.Exec(c[0], c[1:]...); err != nil {
-			if ignoreError {
-				logger.Infof("Run %s failed: %v, ignore it", action, err)
-			} else {
-				return fmt.Errorf("Run %s failed: %v", action, err)
-			}
-		} else {
-			logger.Infof("Run %s succeeded", action)
-		}
-	}
-
-	return nil
-}
-
 // New - initialize Database
 func runModel(model config.ModelConfig, dbConfig config.SubConfig) (err error) {
 	logger := logger.Tag("Database")
@@ -123,126 +104,143 @@ func runModel(model config.ModelConfig, dbConfig config.SubConfig) (err error) {
 
 	logger.Infof("=> database | %v: %v", dbConfig.Type, base.name)
 
+	n := notifier.New(model.Viper)
+	hooks := hook.Load(dbConfig.Viper, "Database")
+	stats := &notifier.Stats{Model: model.Name, StartedAt: time.Now()}
+
 	// before perform
-	beforeScript := dbConfig.Viper.GetString("before_script")
-	if err := runHook("dump before_script", beforeScript); err != nil {
+	if err := hook.RunScript("dump before_script", dbConfig.Viper.GetString("before_script")); err != nil {
 		return err
 	}
 
-	afterScript := dbConfig.Viper.GetString("after_script")
-	onExit := dbConfig.Viper.GetString("on_exit")
-
 	// perform
 	if err = db.init(); err != nil {
 		return
 	}
 
 	if base.sshHost != "" {
-		base.openTunneling()
-		<-base.cTunnelStart
+		if err = base.openTunneling(); err != nil {
+			return
+		}
 	}
 
 	err = db.perform()
 	if base.sshHost != "" {
 		base.closeTunneling()
 	}
+
+	if err == nil {
+		filepath.Walk(base.dumpPath, func(_ string, info os.FileInfo, walkErr error) error {
+			if walkErr == nil && !info.IsDir() {
+				base.dumpSize += info.Size()
+			}
+			return nil
+		})
+	}
+
+	stats.Duration = time.Since(stats.StartedAt)
+	stats.Databases = []notifier.DatabaseStat{{
+		Name:     base.name,
+		Type:     dbConfig.Type,
+		Duration: stats.Duration,
+		Size:     base.dumpSize,
+	}}
+
 	if err != nil {
 		logger.Info("Dump failed")
-		if len(afterScript) == 0 {
-			return
-		} else if len(onExit) != 0 {
-			switch onExit {
-			case "always":
-				logger.Info("on_exit is always, start to run after_script")
-			case "success":
-				logger.Info("on_exit is success, skip run after_script")
-				return
-			case "failure":
-				logger.Info("on_exit is failure, start to run after_script")
-			default:
-				// skip after
-				return
-			}
-		} else {
-			return
+		stats.Error = err
+		if hookErr := hook.Run("Database", hooks, notifier.LevelError, stats, n); hookErr != nil {
+			logger.Errorf("Failed to run error hooks: %v", hookErr)
 		}
-	} else {
-		logger.Info("Dump succeeded")
+		return
 	}
 
-	// after perform
-	if err := runHook("dump after_script", afterScript); err != nil {
-		return err
-	}
+	logger.Info("Dump succeeded")
+	return hook.Run("Database", hooks, notifier.LevelInfo, stats, n)
+}
 
-	return
+// sshConfig builds the shared sshtunnel.Config for this database's bastion
+// host, so every database in a model that points at the same host, port,
+// user and ProxyJump chain reuses a single *ssh.Client instead of dialing
+// its own.
+func (db *Base) sshConfig() sshtunnel.Config {
+	return sshtunnel.Config{
+		Host:                  db.sshHost,
+		Port:                  db.sshPort,
+		User:                  db.sshUser,
+		Password:              db.sshPassword,
+		KeyFile:               db.sshKeyFile,
+		ProxyJump:             db.sshProxyJump,
+		KnownHostsFile:        db.knownHostsFile,
+		StrictHostKeyChecking: db.strictHostKeyChecking,
+		KeepaliveInterval:     db.sshKeepalive,
+	}
 }
 
 func (db *Base) closeTunneling() {
-	db.cTunnelEnd <- struct{}{}
+	logger := logger.Tag("TUNNELING")
+
+	if db.stopForward != nil {
+		db.stopForward()
+		db.stopForward = nil
+	}
+	if db.tunnelClient != nil {
+		db.tunnelClient.Release()
+		db.tunnelClient = nil
+	}
 
+	logger.Info("Tunneling is Stopped")
 }
 
 func (db *Base) openTunneling() error {
-	db.cTunnelEnd = make(chan struct{}, 1)
-	db.cTunnelStart = make(chan struct{}, 1)
-
 	logger := logger.Tag("TUNNELING")
-	var err error
-	sshTun := sshtun.New(db.tunnelLocalPort, db.sshHost, db.tunnelRemotePort)
-	sshTun.SetPort(db.sshPort)
-	sshTun.SetUser(db.sshUser)
-	sshTun.SetPassword(db.sshPassword)
-	if db.sshKeyFile != "" {
-		sshTun.SetKeyFile(db.sshKeyFile)
-	}
-	//
-	sshTun.SetRemoteEndpoint(sshtun.NewTCPEndpoint(db.tunnelDbHost, db.tunnelRemotePort))
-	sshTun.SetLocalEndpoint(sshtun.NewTCPEndpoint("localhost", db.tunnelLocalPort))
-
-	sshTun.SetTunneledConnState(func(tun *sshtun.SSHTun, state *sshtun.TunneledConnState) {
-		logger.Infof("tunneling state %+v", state)
-	})
-
-	// We set a callback to know when the tunnel is ready
-	sshTun.SetConnState(func(tun *sshtun.SSHTun, state sshtun.ConnState) {
-		switch state {
-		case sshtun.StateStarting:
-			logger.Infof("Tunneling is Starting")
-		case sshtun.StateStarted:
-			logger.Infof("Tunneling is Started")
-			db.cTunnelStart <- struct{}{}
-		case sshtun.StateStopped:
-			logger.Infof("Tunneling is Stopped")
-		}
-	})
-
-	go func() {
-		<-db.cTunnelEnd
-		logger.Info("tunneling is Stop")
-		sshTun.Stop()
-	}()
-	go func() {
-		err = sshTun.Start(context.Background())
-		if err != nil {
-			logger.Info("error tunneling:", err)
+	logger.Infof("Tunneling is Starting")
 
-			if len(db.cTunnelStart) > 0 {
-				<-db.cTunnelStart
-			}
-			return
-		}
-	}()
+	client, err := sshtunnel.Get(db.sshConfig())
+	if err != nil {
+		return err
+	}
+	db.tunnelClient = client
+
+	stop, err := client.Forward(db.tunnelLocalPort, db.tunnelDbHost, db.tunnelRemotePort)
+	if err != nil {
+		client.Release()
+		db.tunnelClient = nil
+		return err
+	}
+	db.stopForward = stop
 
-	return err
+	logger.Infof("Tunneling is Started")
+	return nil
 }
 
-// Run databases
+// Run databases, holding the model's run lock (see helper.LockPath) for
+// the duration so an overlapping cron-triggered run of the same model
+// can't dump into a dumpPath this run is still using.
+//
+// This lock is released before Run returns, so the archive step that
+// follows (reading dumpPath to build the uploadable archive) isn't
+// covered by it. helper.Lock takes a new file lock on every call, so a
+// caller that already holds helper.LockPath(model) and then calls Run
+// makes Run's own Lock call find the lock held: Run waits up to
+// lock_timeout and then fails.
 func Run(model config.ModelConfig) error {
 	if len(model.Databases) == 0 {
 		return nil
 	}
 
+	unlock, err := helper.Lock(helper.LockPath(model), model.Viper.GetDuration("lock_timeout"))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	releaseTunnels, err := holdModelTunnels(model)
+	if err != nil {
+		return err
+	}
+	defer releaseTunnels()
+
 	for _, dbCfg := range model.Databases {
 		err := runModel(model, dbCfg)
 		if err != nil {
@@ -252,3 +250,36 @@ func Run(model config.ModelConfig) error {
 
 	return nil
 }
+
+// holdModelTunnels takes one extra sshtunnel reference per database that
+// has ssh_host configured, before any database runs, and releases them
+// only after every database has finished. Each runModel still does its
+// own Get/Release pair around its dump (openTunneling/closeTunneling), but
+// because this holds a reference across the whole loop too, the refcount
+// never drops to zero between databases that share a bastion host - so
+// the underlying *ssh.Client is dialed once per model and reused, instead
+// of being torn down and redialed between every database in turn.
+func holdModelTunnels(model config.ModelConfig) (release func(), err error) {
+	var clients []*sshtunnel.Client
+	for _, dbCfg := range model.Databases {
+		base := newBase(model, dbCfg)
+		if base.sshHost == "" {
+			continue
+		}
+
+		client, err := sshtunnel.Get(base.sshConfig())
+		if err != nil {
+			for _, c := range clients {
+				c.Release()
+			}
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+
+	return func() {
+		for _, c := range clients {
+			c.Release()
+		}
+	}, nil
+}
@@ -0,0 +1,43 @@
+// Package encryption wraps an archive stream with OpenPGP or age
+// encryption before it is handed to a storage.Backend for upload.
+package encryption
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/viper"
+)
+
+// Encryptor wraps a plaintext archive reader with an encrypting reader,
+// streaming the result instead of buffering the whole archive in RAM.
+type Encryptor interface {
+	// Name returns the configured encryption type, e.g. "openpgp", "age".
+	Name() string
+	// Extension is appended to the archive's file name once encrypted,
+	// e.g. ".gpg" or ".age".
+	Extension() string
+	// Encrypt wraps src, the plaintext archive stream, returning an
+	// encrypted stream of the same data. filename is preserved in the
+	// ciphertext metadata where the format supports it (OpenPGP's
+	// literal-data packet).
+	Encrypt(src io.Reader, filename string) (io.Reader, error)
+}
+
+// New builds the Encryptor configured on a model's `encryption:` block, or
+// nil if encryption is not configured.
+func New(v *viper.Viper) (Encryptor, error) {
+	encType := v.GetString("encryption.type")
+	if encType == "" {
+		return nil, nil
+	}
+
+	switch encType {
+	case "openpgp":
+		return newOpenPGP(v), nil
+	case "age":
+		return newAge(v), nil
+	default:
+		return nil, fmt.Errorf("encryption type %s is not implement", encType)
+	}
+}
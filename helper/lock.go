@@ -0,0 +1,65 @@
+package helper
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+
+	"github.com/gobackup/gobackup/config"
+)
+
+// LockPath returns the path of the lock file that database.Run and
+// storage.Run each hold while they dump and upload a model, configurable
+// via `lock_path`, defaulting under the model's workdir so overlapping
+// cron-triggered runs of the same model don't race. The archive step
+// between the two runs is not under this lock.
+func LockPath(model config.ModelConfig) string {
+	if p := model.Viper.GetString("lock_path"); p != "" {
+		return p
+	}
+	return filepath.Join(model.WorkDir, "gobackup.lock")
+}
+
+// Lock acquires an exclusive file lock at path, blocking up to timeout
+// before giving up (timeout <= 0 means try once and fail immediately if
+// already held). It returns an unlock func to release the lock, which the
+// caller must call regardless of how the locked section completes.
+//
+// This guards against overlapping cron-triggered runs stepping on the
+// same dump directory or double-uploading, since a model's dumpPath is
+// deterministic and otherwise has no coordination between runs.
+func Lock(path string, timeout time.Duration) (unlock func(), err error) {
+	fileLock := flock.New(path)
+
+	locked, err := tryLock(fileLock, timeout)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, fmt.Errorf("could not acquire lock %s, another run may still be in progress", path)
+	}
+
+	return func() {
+		fileLock.Unlock()
+	}, nil
+}
+
+func tryLock(fileLock *flock.Flock, timeout time.Duration) (bool, error) {
+	if timeout <= 0 {
+		return fileLock.TryLock()
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := fileLock.TryLock()
+		if err != nil || locked {
+			return locked, err
+		}
+		if time.Now().After(deadline) {
+			return false, nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
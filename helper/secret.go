@@ -0,0 +1,67 @@
+package helper
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// GetStringWithFile reads a string config value from v, transparently
+// supporting Docker/Kubernetes secrets mounted as files: if `<key>_file`
+// is set, its trimmed contents are read and used as the value instead of
+// `key` itself. Setting both the plain key and its `_file` variant is a
+// configuration mistake and panics rather than silently picking one, as
+// does pointing `_file` at a path that can't be read - either way the
+// user would otherwise leak or lose a credential without noticing.
+func GetStringWithFile(v *viper.Viper, key string) string {
+	fileKey := key + "_file"
+	path := v.GetString(fileKey)
+	if path == "" {
+		return v.GetString(key)
+	}
+
+	if plain := v.GetString(key); plain != "" {
+		panic(fmt.Sprintf("both %s and %s are set, please only use one", key, fileKey))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		panic(fmt.Sprintf("failed to read %s from %s: %v", key, path, err))
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+// GetStringSliceWithFile reads a string-slice config value from v, with
+// the same Docker/Kubernetes secret-file support as GetStringWithFile: if
+// `<key>_file` is set, its contents are split into lines and used instead
+// of `key` itself, one entry per non-blank line. Setting both the plain
+// key and its `_file` variant panics, as does pointing `_file` at a path
+// that can't be read.
+func GetStringSliceWithFile(v *viper.Viper, key string) []string {
+	fileKey := key + "_file"
+	path := v.GetString(fileKey)
+	if path == "" {
+		return v.GetStringSlice(key)
+	}
+
+	if plain := v.GetStringSlice(key); len(plain) > 0 {
+		panic(fmt.Sprintf("both %s and %s are set, please only use one", key, fileKey))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		panic(fmt.Sprintf("failed to read %s from %s: %v", key, path, err))
+	}
+
+	var values []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			values = append(values, line)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,116 @@
+// Package hook runs a model's lifecycle scripts and, once they've run,
+// dispatches a notification for the same level. It replaces the old
+// shell-only `runHook` helper that used to live in the database package.
+package hook
+
+import (
+	"strings"
+
+	"github.com/google/shlex"
+	"github.com/spf13/viper"
+
+	"github.com/gobackup/gobackup/helper"
+	"github.com/gobackup/gobackup/logger"
+	"github.com/gobackup/gobackup/notifier"
+)
+
+// Level controls when a Hook fires.
+type Level = notifier.Level
+
+// Hook is a single entry of a model's `hooks:` list.
+type Hook struct {
+	Level  Level
+	Script string
+}
+
+// Load reads a `hooks:` list (level + script entries) from v and adds
+// the legacy single `after_script` as one more hook, leveled by
+// `on_exit`: "always" runs it after every run, "failure" only after a
+// failed one, and any other value only after a successful one. tag labels
+// a parse-failure log line (e.g. "Database", "Storage").
+func Load(v *viper.Viper, tag string) []Hook {
+	var hooks []Hook
+	if err := v.UnmarshalKey("hooks", &hooks); err != nil {
+		logger.Tag(tag).Warnf("Failed to parse hooks: %v", err)
+	}
+
+	if afterScript := v.GetString("after_script"); afterScript != "" {
+		switch v.GetString("on_exit") {
+		case "always":
+			hooks = append(hooks, Hook{Level: notifier.LevelAlways, Script: afterScript})
+		case "failure":
+			hooks = append(hooks, Hook{Level: notifier.LevelError, Script: afterScript})
+		default:
+			hooks = append(hooks, Hook{Level: notifier.LevelInfo, Script: afterScript})
+		}
+	}
+
+	return hooks
+}
+
+// Run executes every hook whose level matches level (or is "always"),
+// tagging its logs with tag, then dispatches a notifier.Notify for level
+// with stats. n may be nil if no notifications are configured.
+//
+// A failing hook script doesn't stop the remaining hooks at this level
+// from running, nor does it suppress the notification - the notification
+// is the thing telling someone their hook is broken, so it must still go
+// out. Run returns the first hook error encountered, if any, after
+// everything has run.
+func Run(tag string, hooks []Hook, level Level, stats *notifier.Stats, n *notifier.Notifier) error {
+	logger := logger.Tag(tag)
+
+	var firstErr error
+	for _, h := range hooks {
+		if h.Level != level && h.Level != notifier.LevelAlways {
+			continue
+		}
+		logger.Infof("Run %s hook", level)
+		if err := RunScript(tag, h.Script); err != nil {
+			logger.Errorf("Hook failed: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if err := n.Notify(level, stats); err != nil {
+		logger.Errorf("Failed to notify: %v", err)
+	}
+
+	return firstErr
+}
+
+// RunScript runs a single shell script, tagging its logs with tag. It is
+// the escape hatch kept around for plain `before_script`/`after_script`
+// config that predates the leveled hooks list. A script prefixed with "-"
+// has its failure logged and ignored rather than returned.
+func RunScript(tag, script string) error {
+	logger := logger.Tag(tag)
+	if len(script) == 0 {
+		return nil
+	}
+
+	ignoreError := strings.HasPrefix(script, "-")
+	script = strings.TrimPrefix(script, "-")
+
+	c, err := shlex.Split(script)
+	if err != nil {
+		if ignoreError {
+			logger.Infof("Skip hook with error: %v", err)
+			return nil
+		}
+		return err
+	}
+
+	if _, err := helper.Exec(c[0], c[1:]...); err != nil {
+		if ignoreError {
+			logger.Infof("Hook failed: %v, ignore it", err)
+			return nil
+		}
+		return err
+	}
+
+	logger.Info("Hook succeeded")
+	return nil
+}
@@ -0,0 +1,124 @@
+package notifier
+
+import (
+	"bytes"
+	"text/template"
+	"time"
+
+	"github.com/containrrr/shoutrrr"
+	"github.com/spf13/viper"
+
+	"github.com/gobackup/gobackup/helper"
+	"github.com/gobackup/gobackup/logger"
+)
+
+// Level controls when a notification fires.
+type Level string
+
+const (
+	LevelInfo   Level = "info"
+	LevelError  Level = "error"
+	LevelAlways Level = "always"
+)
+
+// DatabaseStat records dump timing/size for one database in a model.
+type DatabaseStat struct {
+	Name     string
+	Type     string
+	Duration time.Duration
+	Size     int64
+}
+
+// StorageStat records upload timing/size for one storage in a model.
+type StorageStat struct {
+	Name     string
+	Type     string
+	Duration time.Duration
+	Size     int64
+}
+
+// Stats is the data made available to notification templates for a
+// single model run. Hook scripts do not receive it.
+type Stats struct {
+	Model     string
+	StartedAt time.Time
+	Duration  time.Duration
+	Error     error
+	Databases []DatabaseStat
+	Storages  []StorageStat
+}
+
+const defaultSuccessTemplate = `{{.Model}} backup succeeded in {{.Duration}}`
+const defaultFailureTemplate = `{{.Model}} backup failed: {{.Error}}`
+
+// Notifier renders per-level templates and dispatches them to every
+// configured Shoutrrr URL (Slack, Telegram, Discord, SMTP, Gotify, or a
+// generic webhook).
+type Notifier struct {
+	urls      []string
+	templates map[Level]string
+}
+
+// New builds a Notifier from a model's `notify:` config, e.g.:
+//
+//	notify:
+//	  urls:
+//	    - "slack://token@channel"
+//	  templates:
+//	    error: "backup of {{.Model}} failed: {{.Error}}"
+//
+// urls also accepts the `_file` convention (notify.urls_file), reading one
+// URL per line, since a webhook URL embeds a token just like any other
+// credential and may be mounted as a Docker/Kubernetes secret.
+func New(v *viper.Viper) *Notifier {
+	if v == nil {
+		return &Notifier{}
+	}
+
+	templates := map[Level]string{}
+	for level, tpl := range v.GetStringMapString("notify.templates") {
+		templates[Level(level)] = tpl
+	}
+
+	return &Notifier{
+		urls:      helper.GetStringSliceWithFile(v, "notify.urls"),
+		templates: templates,
+	}
+}
+
+func (n *Notifier) template(level Level) string {
+	if tpl, ok := n.templates[level]; ok && tpl != "" {
+		return tpl
+	}
+	if level == LevelError {
+		return defaultFailureTemplate
+	}
+	return defaultSuccessTemplate
+}
+
+// Notify renders the template registered for level and sends it to every
+// configured URL. A Notifier with no URLs configured is a no-op.
+func (n *Notifier) Notify(level Level, stats *Stats) error {
+	if n == nil || len(n.urls) == 0 {
+		return nil
+	}
+
+	tpl, err := template.New(string(level)).Parse(n.template(level))
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, stats); err != nil {
+		return err
+	}
+
+	logger := logger.Tag("Notifier")
+	for _, url := range n.urls {
+		if err := shoutrrr.Send(url, buf.String()); err != nil {
+			logger.Errorf("Failed to notify %s: %v", url, err)
+		}
+	}
+
+	return nil
+}
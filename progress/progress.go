@@ -0,0 +1,108 @@
+// Package progress draws interactive progress bars for dumps, encryption,
+// and uploads when attached to a TTY, and is a no-op otherwise.
+package progress
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/cheggaaa/pb/v3"
+	"golang.org/x/term"
+)
+
+// Enabled controls whether progress bars are drawn at all. It defaults to
+// whether stderr is a TTY; the --progress/--no-progress CLI flags call
+// SetEnabled to override it.
+var Enabled = term.IsTerminal(int(os.Stderr.Fd()))
+
+// Silent forces every bar to io.Discard regardless of Enabled, set by the
+// --silent CLI flag.
+var Silent = false
+
+var (
+	mu     sync.Mutex
+	active = map[*Bar]struct{}{}
+)
+
+// SetEnabled overrides the TTY-detected default.
+func SetEnabled(v bool) {
+	Enabled = v
+}
+
+// SetSilent forces every bar to io.Discard.
+func SetSilent(v bool) {
+	Silent = v
+}
+
+// Bar wraps a cheggaaa/pb bar. A disabled or silenced Bar still tracks
+// progress internally but renders to io.Discard, so callers never need to
+// branch on whether progress reporting is on.
+type Bar struct {
+	pb *pb.ProgressBar
+}
+
+// New starts a bar for a stream named label with the given total size in
+// bytes. total <= 0 means the size is unknown, so the bar falls back to
+// bytes-transferred with speed only instead of a percentage.
+func New(label string, total int64) *Bar {
+	bar := pb.New64(total)
+	bar.Set("prefix", label+" ")
+	bar.Set(pb.Bytes, true)
+	if total <= 0 {
+		bar.SetTemplateString(`{{ .Prefix }}{{ counters . }} {{ speed . }}`)
+	}
+
+	if Silent || !Enabled {
+		bar.SetWriter(io.Discard)
+	} else {
+		bar.SetWriter(os.Stderr)
+	}
+
+	b := &Bar{pb: bar.Start()}
+
+	mu.Lock()
+	active[b] = struct{}{}
+	mu.Unlock()
+
+	return b
+}
+
+// Reader wraps r so every Read advances the bar.
+func (b *Bar) Reader(r io.Reader) io.Reader {
+	return b.pb.NewProxyReader(r)
+}
+
+// Writer wraps w so every Write advances the bar.
+func (b *Bar) Writer(w io.Writer) io.Writer {
+	return b.pb.NewProxyWriter(w)
+}
+
+// Finish stops the bar and leaves the terminal clean. Safe to call more
+// than once.
+func (b *Bar) Finish() {
+	mu.Lock()
+	delete(active, b)
+	mu.Unlock()
+
+	b.pb.Finish()
+}
+
+// FinishAll finishes every bar still running, leaving the terminal clean.
+// This package owns no signal handling of its own - registering a SIGINT
+// handler here, even one that doesn't call os.Exit, would still suppress
+// Go's default terminate-on-SIGINT behavior for the whole process just by
+// existing. The CLI's own SIGINT handler should call FinishAll before it
+// decides how to shut down.
+func FinishAll() {
+	mu.Lock()
+	bars := make([]*Bar, 0, len(active))
+	for b := range active {
+		bars = append(bars, b)
+	}
+	mu.Unlock()
+
+	for _, b := range bars {
+		b.Finish()
+	}
+}
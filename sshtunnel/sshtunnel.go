@@ -0,0 +1,309 @@
+// Package sshtunnel maintains a single *ssh.Client per bastion host,
+// reference-counted across every consumer that dials through it - a
+// model's databases and, optionally, its SFTP storage backends - instead
+// of each one reconnecting independently.
+package sshtunnel
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/gobackup/gobackup/logger"
+)
+
+// Config describes how to reach a host, optionally through a chain of
+// jump hosts.
+type Config struct {
+	Host                  string
+	Port                  int
+	User                  string
+	Password              string
+	KeyFile               string
+	ProxyJump             []string
+	KnownHostsFile        string
+	StrictHostKeyChecking bool
+	KeepaliveInterval     time.Duration
+}
+
+func (c Config) key() string {
+	return fmt.Sprintf("%s@%s:%d via %s", c.User, c.Host, c.Port, strings.Join(c.ProxyJump, ","))
+}
+
+var (
+	mu      sync.Mutex
+	clients = map[string]*Client{}
+)
+
+// Get returns the shared Client for cfg, dialing it (through any
+// configured ProxyJump chain) on first use. Every call must be matched by
+// exactly one call to Release.
+func Get(cfg Config) (*Client, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	key := cfg.key()
+	if c, ok := clients[key]; ok {
+		c.refCount++
+		return c, nil
+	}
+
+	sshClient, err := dial(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{ssh: sshClient, cfg: cfg, refCount: 1}
+	clients[key] = c
+
+	if cfg.KeepaliveInterval > 0 {
+		go c.keepalive()
+	}
+
+	return c, nil
+}
+
+// Client is a reference-counted wrapper around a shared *ssh.Client.
+type Client struct {
+	ssh      *ssh.Client
+	cfg      Config
+	refCount int
+	closed   bool
+}
+
+// SSHClient returns the underlying *ssh.Client, for consumers (like an
+// SFTP storage backend) that drive their own protocol directly over it.
+func (c *Client) SSHClient() *ssh.Client {
+	return c.ssh
+}
+
+// Dial opens a connection to addr through the tunnel.
+func (c *Client) Dial(network, addr string) (net.Conn, error) {
+	return c.ssh.Dial(network, addr)
+}
+
+// Release drops a reference to the Client, closing the shared connection
+// once the last consumer has released it.
+func (c *Client) Release() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	c.refCount--
+	if c.refCount > 0 {
+		return
+	}
+
+	delete(clients, c.cfg.key())
+	c.closed = true
+	c.ssh.Close()
+}
+
+func (c *Client) keepalive() {
+	ticker := time.NewTicker(c.cfg.KeepaliveInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		mu.Lock()
+		closed := c.closed
+		mu.Unlock()
+		if closed {
+			return
+		}
+
+		if _, _, err := c.ssh.SendRequest("keepalive@gobackup", true, nil); err != nil {
+			logger.Tag("SSH Tunnel").Warnf("Keepalive failed: %v", err)
+			return
+		}
+	}
+}
+
+// Forward opens a local listener on localPort that proxies every
+// connection it accepts through the tunnel to remoteHost:remotePort, for
+// consumers (like a database dump) that expect to connect to a plain
+// local address. It returns a stop func that tears the listener down; the
+// shared SSH client itself stays up until every consumer calls Release.
+func (c *Client) Forward(localPort int, remoteHost string, remotePort int) (stop func(), err error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", localPort))
+	if err != nil {
+		return nil, err
+	}
+
+	logger := logger.Tag("SSH Tunnel")
+	closing := make(chan struct{})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				select {
+				case <-closing:
+					return
+				default:
+					logger.Warnf("Accept failed: %v", err)
+					return
+				}
+			}
+			go c.proxy(conn, remoteHost, remotePort)
+		}
+	}()
+
+	return func() {
+		close(closing)
+		listener.Close()
+	}, nil
+}
+
+func (c *Client) proxy(local net.Conn, remoteHost string, remotePort int) {
+	defer local.Close()
+
+	remote, err := c.ssh.Dial("tcp", fmt.Sprintf("%s:%d", remoteHost, remotePort))
+	if err != nil {
+		logger.Tag("SSH Tunnel").Warnf("Dial %s:%d failed: %v", remoteHost, remotePort, err)
+		return
+	}
+	defer remote.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); io.Copy(remote, local) }()
+	go func() { defer wg.Done(); io.Copy(local, remote) }()
+	wg.Wait()
+}
+
+func dial(cfg Config) (*ssh.Client, error) {
+	hostKeyCallback, err := hostKeyCallback(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := authMethods(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	finalConfig := &ssh.ClientConfig{
+		User:            cfg.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         15 * time.Second,
+	}
+	finalAddr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+
+	if len(cfg.ProxyJump) == 0 {
+		return ssh.Dial("tcp", finalAddr, finalConfig)
+	}
+
+	return dialThroughJumps(cfg.ProxyJump, finalAddr, finalConfig, hostKeyCallback, auth)
+}
+
+// dialThroughJumps walks a ProxyJump chain (mirroring ssh -J), dialing
+// each hop through the previous one's client, then dials finalAddr
+// through the last hop and completes the handshake there with
+// finalConfig.
+func dialThroughJumps(jumps []string, finalAddr string, finalConfig *ssh.ClientConfig, hostKeyCallback ssh.HostKeyCallback, auth []ssh.AuthMethod) (*ssh.Client, error) {
+	var client *ssh.Client
+
+	for _, jump := range jumps {
+		user, addr := parseJump(jump)
+		jumpConfig := &ssh.ClientConfig{
+			User:            user,
+			Auth:            auth,
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         15 * time.Second,
+		}
+
+		var (
+			conn net.Conn
+			err  error
+		)
+		if client == nil {
+			conn, err = net.DialTimeout("tcp", addr, jumpConfig.Timeout)
+		} else {
+			conn, err = client.Dial("tcp", addr)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jump to %s: %w", addr, err)
+		}
+
+		connConn, chans, reqs, err := ssh.NewClientConn(conn, addr, jumpConfig)
+		if err != nil {
+			return nil, fmt.Errorf("ssh handshake with %s: %w", addr, err)
+		}
+		client = ssh.NewClient(connConn, chans, reqs)
+	}
+
+	conn, err := client.Dial("tcp", finalAddr)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", finalAddr, err)
+	}
+
+	connConn, chans, reqs, err := ssh.NewClientConn(conn, finalAddr, finalConfig)
+	if err != nil {
+		return nil, fmt.Errorf("ssh handshake with %s: %w", finalAddr, err)
+	}
+
+	return ssh.NewClient(connConn, chans, reqs), nil
+}
+
+// parseJump parses a ProxyJump entry in `user@host:port` form, defaulting
+// user to "root" and port to 22 when omitted.
+func parseJump(jump string) (user, addr string) {
+	user = "root"
+	host := jump
+	port := 22
+
+	if i := strings.Index(host, "@"); i >= 0 {
+		user = host[:i]
+		host = host[i+1:]
+	}
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		if p, err := strconv.Atoi(host[i+1:]); err == nil {
+			port = p
+			host = host[:i]
+		}
+	}
+
+	return user, fmt.Sprintf("%s:%d", host, port)
+}
+
+func hostKeyCallback(cfg Config) (ssh.HostKeyCallback, error) {
+	if !cfg.StrictHostKeyChecking {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	path := cfg.KnownHostsFile
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	return knownhosts.New(path)
+}
+
+func authMethods(cfg Config) ([]ssh.AuthMethod, error) {
+	if cfg.KeyFile != "" {
+		data, err := os.ReadFile(cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			return nil, err
+		}
+		return []ssh.AuthMethod{ssh.PublicKeys(signer)}, nil
+	}
+
+	return []ssh.AuthMethod{ssh.Password(cfg.Password)}, nil
+}
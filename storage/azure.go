@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+
+	"github.com/gobackup/gobackup/helper"
+	"github.com/gobackup/gobackup/logger"
+)
+
+// Azure storage
+//
+// type: azure
+// container: my-backups
+// account: myaccount
+// account_key_file: /etc/gobackup/azure_account_key
+type Azure struct {
+	Base
+	client      *azblob.Client
+	containerID string
+	path        string
+}
+
+func (s *Azure) Name() string {
+	return "azure"
+}
+
+func (s *Azure) Open() error {
+	account := s.viper.GetString("account")
+	accountKey := helper.GetStringWithFile(s.viper, "account_key")
+	s.containerID = s.viper.GetString("container")
+	s.path = strings.TrimPrefix(s.viper.GetString("path"), "/")
+
+	cred, err := azblob.NewSharedKeyCredential(account, accountKey)
+	if err != nil {
+		return err
+	}
+
+	serviceURL := "https://" + account + ".blob.core.windows.net/"
+	client, err := azblob.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
+	if err != nil {
+		return err
+	}
+	s.client = client
+
+	return nil
+}
+
+func (s *Azure) Close() error {
+	return nil
+}
+
+func (s *Azure) key(fileKey string) string {
+	if s.path == "" {
+		return fileKey
+	}
+	return s.path + "/" + fileKey
+}
+
+func (s *Azure) Upload(fileKey string, reader io.Reader) error {
+	logger := logger.Tag("Azure")
+
+	_, err := s.client.UploadStream(context.Background(), s.containerID, s.key(fileKey), reader, nil)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Upload succeeded", fileKey)
+	return nil
+}
+
+func (s *Azure) Download(fileKey string) (io.ReadCloser, error) {
+	resp, err := s.client.DownloadStream(context.Background(), s.containerID, s.key(fileKey), nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *Azure) Delete(fileKey string) error {
+	_, err := s.client.DeleteBlob(context.Background(), s.containerID, s.key(fileKey), nil)
+	return err
+}
+
+func (s *Azure) List(parent string) ([]FileItem, error) {
+	var items []FileItem
+
+	prefix := s.key(parent)
+	pager := s.client.NewListBlobsFlatPager(s.containerID, &container.ListBlobsFlatOptions{
+		Prefix: &prefix,
+	})
+	for pager.More() {
+		page, err := pager.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, blob := range page.Segment.BlobItems {
+			items = append(items, FileItem{
+				Filename:     *blob.Name,
+				Size:         *blob.Properties.ContentLength,
+				LastModified: *blob.Properties.LastModified,
+			})
+		}
+	}
+
+	return items, nil
+}
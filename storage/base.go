@@ -0,0 +1,301 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/gobackup/gobackup/config"
+	"github.com/gobackup/gobackup/encryption"
+	"github.com/gobackup/gobackup/helper"
+	"github.com/gobackup/gobackup/hook"
+	"github.com/gobackup/gobackup/logger"
+	"github.com/gobackup/gobackup/notifier"
+	"github.com/gobackup/gobackup/progress"
+)
+
+// FileItem is a single remote file as reported by Backend.List.
+type FileItem struct {
+	Filename     string
+	Size         int64
+	LastModified time.Time
+}
+
+// Base storage, embedded by every Backend implementation.
+type Base struct {
+	model config.ModelConfig
+	viper *viper.Viper
+	name  string
+}
+
+// Backend is implemented by every storage provider gobackup knows how to
+// upload an archive to. Providers are looked up by the `type:` key of a
+// model's storage config and registered in the package-level registry below.
+type Backend interface {
+	// Name returns the backend's registered type, e.g. "local", "s3".
+	Name() string
+	// Open prepares the backend for use, e.g. establishing a connection.
+	Open() error
+	// Close releases any resources acquired by Open.
+	Close() error
+	// Upload streams reader to fileKey. reader yields the archive, or its
+	// encrypted form when encryption is configured on the model.
+	Upload(fileKey string, reader io.Reader) error
+	// Download returns a reader for the remote file at fileKey.
+	Download(fileKey string) (io.ReadCloser, error)
+	// List lists the files stored under parent.
+	List(parent string) ([]FileItem, error)
+	// Delete removes the remote file at fileKey.
+	Delete(fileKey string) error
+}
+
+// registry maps a storage config `type:` to its Backend constructor.
+var registry = map[string]func(Base) Backend{
+	"local":   func(b Base) Backend { return &Local{Base: b} },
+	"azure":   func(b Base) Backend { return &Azure{Base: b} },
+	"dropbox": func(b Base) Backend { return &Dropbox{Base: b} },
+	"webdav":  func(b Base) Backend { return &WebDAV{Base: b} },
+	"sftp":    func(b Base) Backend { return &SFTP{Base: b} },
+	"s3":      func(b Base) Backend { return &S3{Base: b} },
+}
+
+func newBase(model config.ModelConfig, storageConfig config.SubConfig) Base {
+	return Base{
+		model: model,
+		viper: storageConfig.Viper,
+		name:  storageConfig.Name,
+	}
+}
+
+func newBackend(storageType string, base Base) (Backend, error) {
+	ctor, ok := registry[storageType]
+	if !ok {
+		return nil, fmt.Errorf("storage type %s is not implement", storageType)
+	}
+	return ctor(base), nil
+}
+
+func runBackend(model config.ModelConfig, storageConfig config.SubConfig, fileKey string, reader io.Reader, expectedSize int64) (err error) {
+	logger := logger.Tag("Storage")
+
+	base := newBase(model, storageConfig)
+	backend, err := newBackend(storageConfig.Type, base)
+	if err != nil {
+		logger.Warn(err)
+		return err
+	}
+
+	logger.Infof("=> storage | %v: %v", storageConfig.Type, base.name)
+
+	n := notifier.New(model.Viper)
+	hooks := hook.Load(storageConfig.Viper, "Storage")
+	stats := &notifier.Stats{Model: model.Name, StartedAt: time.Now()}
+
+	if err := hook.RunScript("upload before_script", storageConfig.Viper.GetString("before_script")); err != nil {
+		return err
+	}
+
+	if err = backend.Open(); err != nil {
+		return err
+	}
+	defer backend.Close()
+
+	counter := &countingReader{r: reader}
+	bar := progress.New("Upload "+base.name, expectedSize)
+	err = backend.Upload(fileKey, bar.Reader(counter))
+	bar.Finish()
+
+	stats.Duration = time.Since(stats.StartedAt)
+	stats.Storages = []notifier.StorageStat{{
+		Name:     base.name,
+		Type:     storageConfig.Type,
+		Duration: stats.Duration,
+		Size:     counter.n,
+	}}
+
+	if err != nil {
+		stats.Error = err
+		if hookErr := hook.Run("Storage", hooks, notifier.LevelError, stats, n); hookErr != nil {
+			logger.Errorf("Failed to run error hooks: %v", hookErr)
+		}
+		return err
+	}
+
+	return hook.Run("Storage", hooks, notifier.LevelInfo, stats, n)
+}
+
+// countingReader tracks the number of bytes read through it, so a
+// backend's upload size can be reported without a second pass over the
+// stream or a stat call that wouldn't reflect encrypted size anyway.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Run uploads archivePath to every storage configured on the model.
+//
+// When encryption is configured, the archive is encrypted once and the
+// resulting ciphertext stream is fanned out to every storage; otherwise
+// the plain archive is fanned out directly. Either way nothing is
+// buffered in RAM: a single goroutine tees the source into a pipe per
+// storage, and each storage reads its own pipe concurrently.
+//
+// Multiple storages run concurrently via errgroup, mirroring the
+// multi-destination upload pattern used by offen/docker-volume-backup: a
+// failure on one storage is reported without blocking the others from
+// finishing their own uploads. A backend that gives up early closes its
+// pipe's read side (see the g.Go body below), which makes fanOut drop
+// that one destination instead of stalling on it.
+//
+// Run holds the model's run lock (see helper.LockPath) for the duration,
+// same as database.Run, so an overlapping cron-triggered run of the same
+// model can't upload while this run still is. Because each of Run and
+// database.Run takes and releases the lock independently, the archive
+// step in between isn't covered by either. helper.Lock takes a new file
+// lock on every call, so a caller that already holds the same lock and
+// then calls Run makes Run wait up to lock_timeout and then fail.
+func Run(model config.ModelConfig, archivePath string) error {
+	if len(model.Storages) == 0 {
+		return nil
+	}
+
+	unlock, err := helper.Lock(helper.LockPath(model), model.Viper.GetDuration("lock_timeout"))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fileName := filepath.Base(archivePath)
+	var src io.Reader = f
+
+	// Known upfront only for the plaintext archive; once encrypted the
+	// ciphertext size isn't known ahead of time, so the upload bars fall
+	// back to bytes-transferred with speed only.
+	expectedSize := int64(0)
+	if info, statErr := f.Stat(); statErr == nil {
+		expectedSize = info.Size()
+	}
+
+	enc, err := encryption.New(model.Viper)
+	if err != nil {
+		return err
+	}
+	if enc != nil {
+		src, err = enc.Encrypt(f, fileName)
+		if err != nil {
+			return err
+		}
+		fileName += enc.Extension()
+		expectedSize = 0
+	}
+
+	readers := fanOut(src, len(model.Storages))
+
+	g, _ := errgroup.WithContext(context.Background())
+	for i, storageConfig := range model.Storages {
+		i, storageConfig := i, storageConfig
+		fileKey := model.Name + "/" + fileName
+		g.Go(func() error {
+			err := runBackend(model, storageConfig, fileKey, readers[i], expectedSize)
+			// Closing our read side unblocks fanOut's tee goroutine if it's
+			// mid-write to us (e.g. because Upload gave up without reading
+			// to EOF), so one backend's failure can't stall the others.
+			if pr, ok := readers[i].(*io.PipeReader); ok {
+				pr.CloseWithError(err)
+			}
+			return err
+		})
+	}
+
+	return g.Wait()
+}
+
+// fanOut returns n readers that each yield every byte of src, without
+// buffering src in memory: a single goroutine tees src into n pipes. A
+// destination whose reader stops being consumed (its backend errored or
+// returned early) is dropped from the tee instead of stalling the copy -
+// see tee below - so the other destinations keep receiving data.
+func fanOut(src io.Reader, n int) []io.Reader {
+	if n <= 1 {
+		return []io.Reader{src}
+	}
+
+	writers := make([]*io.PipeWriter, n)
+	readers := make([]io.Reader, n)
+	for i := 0; i < n; i++ {
+		pr, pw := io.Pipe()
+		writers[i] = pw
+		readers[i] = pr
+	}
+
+	go tee(src, writers)
+
+	return readers
+}
+
+// tee copies src into every writer. Unlike io.Copy into an io.MultiWriter,
+// a write error on one writer (because its paired PipeReader was closed by
+// a consumer that gave up) only drops that one writer from future writes;
+// it neither blocks nor aborts delivery to the rest.
+//
+// Once every writer has dropped out, tee keeps reading src to EOF and
+// discarding it rather than returning early: when encryption is
+// configured, src is itself a pipe fed by the encryptor's own copy
+// goroutine, and abandoning it here without reading further would leave
+// that goroutine blocked forever on a Write nobody will ever read, a
+// leaked goroutine and file descriptor per occurrence.
+func tee(src io.Reader, writers []*io.PipeWriter) {
+	alive := make([]bool, len(writers))
+	remaining := len(writers)
+	for i := range alive {
+		alive[i] = true
+	}
+
+	buf := make([]byte, 32*1024)
+	for {
+		nr, rerr := src.Read(buf)
+		if nr > 0 && remaining > 0 {
+			chunk := buf[:nr]
+			for i, w := range writers {
+				if !alive[i] {
+					continue
+				}
+				if _, werr := w.Write(chunk); werr != nil {
+					alive[i] = false
+					remaining--
+				}
+			}
+		}
+		if rerr != nil {
+			for i, w := range writers {
+				if !alive[i] {
+					continue
+				}
+				if rerr == io.EOF {
+					w.Close()
+				} else {
+					w.CloseWithError(rerr)
+				}
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/v6/dropbox/files"
+
+	"github.com/gobackup/gobackup/helper"
+	"github.com/gobackup/gobackup/logger"
+)
+
+// Dropbox storage
+//
+// type: dropbox
+// access_token: xxx
+// path: /backups
+type Dropbox struct {
+	Base
+	client files.Client
+	path   string
+}
+
+func (s *Dropbox) Name() string {
+	return "dropbox"
+}
+
+func (s *Dropbox) Open() error {
+	s.path = "/" + strings.Trim(s.viper.GetString("path"), "/")
+
+	config := dropbox.Config{
+		Token: helper.GetStringWithFile(s.viper, "access_token"),
+	}
+	s.client = files.New(config)
+
+	return nil
+}
+
+func (s *Dropbox) Close() error {
+	return nil
+}
+
+func (s *Dropbox) key(fileKey string) string {
+	return s.path + "/" + fileKey
+}
+
+func (s *Dropbox) Upload(fileKey string, reader io.Reader) error {
+	logger := logger.Tag("Dropbox")
+
+	arg := files.NewUploadArg(s.key(fileKey))
+	arg.Mode.Tag = "overwrite"
+	if _, err := s.client.Upload(arg, reader); err != nil {
+		return err
+	}
+
+	logger.Info("Upload succeeded", fileKey)
+	return nil
+}
+
+func (s *Dropbox) Download(fileKey string) (io.ReadCloser, error) {
+	_, r, err := s.client.Download(files.NewDownloadArg(s.key(fileKey)))
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *Dropbox) Delete(fileKey string) error {
+	_, err := s.client.DeleteV2(files.NewDeleteArg(s.key(fileKey)))
+	return err
+}
+
+func (s *Dropbox) List(parent string) ([]FileItem, error) {
+	var items []FileItem
+
+	res, err := s.client.ListFolder(files.NewListFolderArg(s.key(parent)))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range res.Entries {
+		if meta, ok := entry.(*files.FileMetadata); ok {
+			items = append(items, FileItem{
+				Filename:     meta.Name,
+				Size:         int64(meta.Size),
+				LastModified: time.Time(meta.ServerModified),
+			})
+		}
+	}
+
+	return items, nil
+}
@@ -1,7 +1,7 @@
 package storage
 
 import (
-	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
@@ -19,14 +19,20 @@ type Local struct {
 	path string
 }
 
-func (s *Local) open() error {
+func (s *Local) Name() string {
+	return "local"
+}
+
+func (s *Local) Open() error {
 	s.path = s.viper.GetString("path")
 	return helper.MkdirP(s.path)
 }
 
-func (s *Local) close() {}
+func (s *Local) Close() error {
+	return nil
+}
 
-func (s *Local) upload(fileKey string) (err error) {
+func (s *Local) Upload(fileKey string, reader io.Reader) (err error) {
 	logger := logger.Tag("Local")
 
 	// Related path
@@ -38,7 +44,12 @@ func (s *Local) upload(fileKey string) (err error) {
 	targetDir := path.Dir(targetPath)
 	helper.MkdirP(targetDir)
 
-	_, err = helper.Exec("cp", "-a", s.archivePath, targetPath)
+	f, err := os.Create(targetPath)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, reader)
+	f.Close()
 	if err != nil {
 		return err
 	}
@@ -56,7 +67,7 @@ func (s *Local) upload(fileKey string) (err error) {
 	return nil
 }
 
-func (s *Local) delete(fileKey string) (err error) {
+func (s *Local) Delete(fileKey string) (err error) {
 	targetPath := filepath.Join(s.path, fileKey)
 	logger.Info("Deleting", targetPath)
 
@@ -64,7 +75,7 @@ func (s *Local) delete(fileKey string) (err error) {
 }
 
 // List all files
-func (s *Local) list(parent string) ([]FileItem, error) {
+func (s *Local) List(parent string) ([]FileItem, error) {
 	remotePath := filepath.Join(s.path, parent)
 	var items = []FileItem{}
 
@@ -91,6 +102,6 @@ func (s *Local) list(parent string) ([]FileItem, error) {
 	return items, nil
 }
 
-func (s *Local) download(fileKey string) (string, error) {
-	return "", fmt.Errorf("Local is not support download")
+func (s *Local) Download(fileKey string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.path, fileKey))
 }
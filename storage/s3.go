@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"path"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/gobackup/gobackup/helper"
+	"github.com/gobackup/gobackup/logger"
+)
+
+// S3 storage, implemented natively with the minio-go client so that
+// gobackup does not depend on the `s3cmd`/`rclone` binaries.
+//
+// type: s3
+// bucket: my-backups
+// region: us-east-1
+// access_key_id: xxx
+// secret_access_key: xxx
+// endpoint: s3.amazonaws.com (optional, for S3-compatible services)
+// path: /backups
+type S3 struct {
+	Base
+	client *minio.Client
+	bucket string
+	path   string
+}
+
+func (s *S3) Name() string {
+	return "s3"
+}
+
+func (s *S3) Open() error {
+	s.bucket = s.viper.GetString("bucket")
+	s.path = s.viper.GetString("path")
+
+	endpoint := s.viper.GetString("endpoint")
+	if endpoint == "" {
+		endpoint = "s3.amazonaws.com"
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds: credentials.NewStaticV4(
+			helper.GetStringWithFile(s.viper, "access_key_id"),
+			helper.GetStringWithFile(s.viper, "secret_access_key"),
+			"",
+		),
+		Secure: true,
+		Region: s.viper.GetString("region"),
+	})
+	if err != nil {
+		return err
+	}
+	s.client = client
+
+	return nil
+}
+
+func (s *S3) Close() error {
+	return nil
+}
+
+func (s *S3) key(fileKey string) string {
+	return path.Join(s.path, fileKey)
+}
+
+func (s *S3) Upload(fileKey string, reader io.Reader) error {
+	logger := logger.Tag("S3")
+
+	// -1 tells minio-go the size is unknown upfront, which is the case
+	// once the archive may be streamed through encryption; it falls back
+	// to multipart upload instead of a single PUT.
+	_, err := s.client.PutObject(context.Background(), s.bucket, s.key(fileKey), reader, -1, minio.PutObjectOptions{})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Upload succeeded", fileKey)
+	return nil
+}
+
+func (s *S3) Download(fileKey string) (io.ReadCloser, error) {
+	return s.client.GetObject(context.Background(), s.bucket, s.key(fileKey), minio.GetObjectOptions{})
+}
+
+func (s *S3) Delete(fileKey string) error {
+	return s.client.RemoveObject(context.Background(), s.bucket, s.key(fileKey), minio.RemoveObjectOptions{})
+}
+
+func (s *S3) List(parent string) ([]FileItem, error) {
+	var items []FileItem
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: s.key(parent)}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		items = append(items, FileItem{
+			Filename:     obj.Key,
+			Size:         obj.Size,
+			LastModified: obj.LastModified,
+		})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"io"
+	"path"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/gobackup/gobackup/helper"
+	"github.com/gobackup/gobackup/logger"
+	"github.com/gobackup/gobackup/sshtunnel"
+)
+
+// SFTP storage
+//
+// type: sftp
+// host: example.com
+// port: 22
+// username: foo
+// password: bar
+// path: /backups
+// share_ssh_tunnel: true  # reuse the model's shared SSH client instead of dialing its own
+type SFTP struct {
+	Base
+	tunnel    *sshtunnel.Client
+	sshClient *ssh.Client
+	client    *sftp.Client
+	path      string
+}
+
+func (s *SFTP) Name() string {
+	return "sftp"
+}
+
+func (s *SFTP) sshConfig() sshtunnel.Config {
+	return sshtunnel.Config{
+		Host:                  s.viper.GetString("host"),
+		Port:                  s.viper.GetInt("port"),
+		User:                  s.viper.GetString("username"),
+		Password:              helper.GetStringWithFile(s.viper, "password"),
+		KeyFile:               s.viper.GetString("key_file"),
+		ProxyJump:             s.viper.GetStringSlice("proxy_jump"),
+		KnownHostsFile:        s.viper.GetString("known_hosts_file"),
+		StrictHostKeyChecking: s.viper.GetBool("strict_host_key_checking"),
+		KeepaliveInterval:     s.viper.GetDuration("keepalive_interval"),
+	}
+}
+
+func (s *SFTP) Open() error {
+	s.path = s.viper.GetString("path")
+
+	var sshClient *ssh.Client
+	if s.viper.GetBool("share_ssh_tunnel") {
+		tunnel, err := sshtunnel.Get(s.sshConfig())
+		if err != nil {
+			return err
+		}
+		s.tunnel = tunnel
+		sshClient = tunnel.SSHClient()
+	} else {
+		config := &ssh.ClientConfig{
+			User:            s.viper.GetString("username"),
+			Auth:            []ssh.AuthMethod{ssh.Password(helper.GetStringWithFile(s.viper, "password"))},
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		}
+
+		addr := s.viper.GetString("host") + ":" + s.viper.GetString("port")
+		dialed, err := ssh.Dial("tcp", addr, config)
+		if err != nil {
+			return err
+		}
+		s.sshClient = dialed
+		sshClient = dialed
+	}
+
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		s.Close()
+		return err
+	}
+	s.client = client
+
+	return s.client.MkdirAll(s.path)
+}
+
+func (s *SFTP) Close() error {
+	if s.client != nil {
+		s.client.Close()
+	}
+	if s.tunnel != nil {
+		s.tunnel.Release()
+	}
+	if s.sshClient != nil {
+		s.sshClient.Close()
+	}
+	return nil
+}
+
+func (s *SFTP) key(fileKey string) string {
+	return path.Join(s.path, fileKey)
+}
+
+func (s *SFTP) Upload(fileKey string, reader io.Reader) error {
+	logger := logger.Tag("SFTP")
+
+	targetPath := s.key(fileKey)
+	if err := s.client.MkdirAll(path.Dir(targetPath)); err != nil {
+		return err
+	}
+
+	dst, err := s.client.Create(targetPath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, reader); err != nil {
+		return err
+	}
+
+	logger.Info("Upload succeeded", targetPath)
+	return nil
+}
+
+func (s *SFTP) Download(fileKey string) (io.ReadCloser, error) {
+	return s.client.Open(s.key(fileKey))
+}
+
+func (s *SFTP) Delete(fileKey string) error {
+	return s.client.Remove(s.key(fileKey))
+}
+
+func (s *SFTP) List(parent string) ([]FileItem, error) {
+	var items []FileItem
+
+	files, err := s.client.ReadDir(s.key(parent))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if !file.IsDir() {
+			items = append(items, FileItem{
+				Filename:     file.Name(),
+				Size:         file.Size(),
+				LastModified: file.ModTime(),
+			})
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"io"
+	"path"
+
+	"github.com/studio-b12/gowebdav"
+
+	"github.com/gobackup/gobackup/helper"
+	"github.com/gobackup/gobackup/logger"
+)
+
+// WebDAV storage
+//
+// type: webdav
+// endpoint: https://dav.example.com/remote.php/webdav
+// username: foo
+// password: bar
+// path: /backups
+type WebDAV struct {
+	Base
+	client *gowebdav.Client
+	path   string
+}
+
+func (s *WebDAV) Name() string {
+	return "webdav"
+}
+
+func (s *WebDAV) Open() error {
+	s.path = s.viper.GetString("path")
+
+	s.client = gowebdav.NewClient(
+		s.viper.GetString("endpoint"),
+		s.viper.GetString("username"),
+		helper.GetStringWithFile(s.viper, "password"),
+	)
+
+	return s.client.MkdirAll(s.path, 0755)
+}
+
+func (s *WebDAV) Close() error {
+	return nil
+}
+
+func (s *WebDAV) key(fileKey string) string {
+	return path.Join(s.path, fileKey)
+}
+
+func (s *WebDAV) Upload(fileKey string, reader io.Reader) error {
+	logger := logger.Tag("WebDAV")
+
+	targetKey := s.key(fileKey)
+	if err := s.client.MkdirAll(path.Dir(targetKey), 0755); err != nil {
+		return err
+	}
+
+	if err := s.client.WriteStream(targetKey, reader, 0644); err != nil {
+		return err
+	}
+
+	logger.Info("Upload succeeded", targetKey)
+	return nil
+}
+
+func (s *WebDAV) Download(fileKey string) (io.ReadCloser, error) {
+	return s.client.ReadStream(s.key(fileKey))
+}
+
+func (s *WebDAV) Delete(fileKey string) error {
+	return s.client.Remove(s.key(fileKey))
+}
+
+func (s *WebDAV) List(parent string) ([]FileItem, error) {
+	var items []FileItem
+
+	files, err := s.client.ReadDir(s.key(parent))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if !file.IsDir() {
+			items = append(items, FileItem{
+				Filename:     file.Name(),
+				Size:         file.Size(),
+				LastModified: file.ModTime(),
+			})
+		}
+	}
+
+	return items, nil
+}